@@ -0,0 +1,64 @@
+package sse
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff computes the delay to wait before the next reconnect attempt.
+// attempt is the number of consecutive failed attempts since the last successful
+// connection (starting at 1), and serverRetry is the reconnection time requested by
+// the server via the SSE "retry:" field (or the decoder's default if none was sent).
+type Backoff interface {
+	NextDelay(attempt int, serverRetry time.Duration) time.Duration
+}
+
+// ExponentialBackoff is the default Backoff implementation. It doubles the server-requested
+// retry delay on every consecutive failed attempt, up to Max, and applies random jitter so
+// that many clients reconnecting at once don't all retry in lockstep (thundering herd).
+type ExponentialBackoff struct {
+	// Max caps the computed delay. A zero value means 30 seconds.
+	Max time.Duration
+	// Min floors serverRetry before doubling, so a server-sent "retry: 0" (or the
+	// decoder's zero-value default) can't collapse reconnects into a tight, no-delay
+	// loop. A zero value means 1 second.
+	Min time.Duration
+}
+
+// NewExponentialBackoff returns an ExponentialBackoff capped at max.
+func NewExponentialBackoff(max time.Duration) *ExponentialBackoff {
+	return &ExponentialBackoff{Max: max}
+}
+
+func (b *ExponentialBackoff) NextDelay(attempt int, serverRetry time.Duration) time.Duration {
+	max := b.Max
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	min := b.Min
+	if min <= 0 {
+		min = 1 * time.Second
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := serverRetry
+	if delay < min {
+		delay = min
+	}
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	// Equal jitter: half the delay is guaranteed, the other half randomized, so the
+	// Min floor actually bounds the returned delay from below instead of full jitter
+	// (which distributes over [0, delay] and can still land near zero).
+	half := delay / 2
+	jittered := half + time.Duration(rand.Int63n(int64(half)+1))
+	if jittered < min {
+		jittered = min
+	}
+	return jittered
+}
@@ -0,0 +1,33 @@
+package sse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffFloorsZeroServerRetry(t *testing.T) {
+	b := NewExponentialBackoff(0)
+	min := time.Second // ExponentialBackoff's default Min
+	for i := 0; i < 200; i++ {
+		d := b.NextDelay(1, 0)
+		if d < min {
+			t.Fatalf("NextDelay(1, 0) = %v, want at least the %v floor", d, min)
+		}
+		if d > time.Second {
+			t.Fatalf("NextDelay(1, 0) = %v, want at most the 1s floor", d)
+		}
+	}
+}
+
+func TestExponentialBackoffGrowsAndCaps(t *testing.T) {
+	b := &ExponentialBackoff{Max: 4 * time.Second, Min: time.Second}
+	for i := 0; i < 200; i++ {
+		d := b.NextDelay(10, time.Second)
+		if d < b.Min {
+			t.Fatalf("NextDelay(10, 1s) = %v, want at least Min %v", d, b.Min)
+		}
+		if d > b.Max {
+			t.Fatalf("NextDelay(10, 1s) = %v, want at most Max %v", d, b.Max)
+		}
+	}
+}
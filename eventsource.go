@@ -1,9 +1,11 @@
 package sse
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 )
@@ -16,14 +18,72 @@ var (
 	ErrContentType = errors.New("eventsource: the content type of the stream is not allowed")
 )
 
+// errorsChanBuffer is the capacity of EventSource.errs. Once full, the oldest queued
+// error is dropped to make room so that a slow or absent reader of Errors() can never
+// block the stream.
+const errorsChanBuffer = 16
+
+// defaultEventName is the name events without an explicit `event:` field are
+// dispatched under, matching the browser EventSource's onmessage.
+const defaultEventName = "message"
+
+// defaultListenerBuffer is the default per-listener channel capacity used by
+// MessageEvents() and AddEventListener(). Once full, the oldest queued event is
+// dropped to make room so a slow consumer can never block the stream.
+const defaultListenerBuffer = 16
+
+// subscriptionErrorBodySnippet caps how much of a non-2xx response body is read into
+// SubscriptionError.Message.
+const subscriptionErrorBodySnippet = 1024
+
+// heartbeatsChanBuffer is the capacity of EventSource.heartbeats. A heartbeat is just a
+// liveness signal, so only the most recent one matters; once full, new heartbeats are
+// dropped rather than the send blocking the liveness reader.
+const heartbeatsChanBuffer = 1
+
+// SubscriptionError represents a non-2xx HTTP response received while connecting or
+// reconnecting to the stream.
+type SubscriptionError struct {
+	Code    int
+	Message string
+	// Permanent indicates the server has told the client not to reconnect
+	// (e.g. a 401, 403, 404 or 410 response).
+	Permanent bool
+}
+
+func (e *SubscriptionError) Error() string {
+	return "eventsource: subscription failed: " + e.Message
+}
+
 type (
 	// EventSource connects and processes events from an SSE stream.
 	EventSource struct {
 		url         string
+		urlMux      sync.RWMutex
 		lastEventID string
 		d           *Decoder
 		resp        *http.Response
-		out         chan *MessageEvent
+		errs        chan error
+		done        chan struct{}
+		finalErr    error
+		heartbeats  chan struct{}
+
+		// listeners holds, per event name, the channels subscribed to that name.
+		// The "message" key always holds at least the channel returned by MessageEvents().
+		listeners      map[string][]chan *MessageEvent
+		listenersMux   sync.Mutex
+		listenerBuffer int
+		defaultCh      chan *MessageEvent
+		// closed is set under listenersMux by closeWithError before it closes the
+		// listener channels, so a dispatch racing with it sees either "not yet closed"
+		// (safe to send) or "closed" (skip sending) and never sends on a closed channel.
+		closed bool
+
+		client      *http.Client
+		req         *http.Request
+		backoff     Backoff
+		attempt     int
+		readTimeout time.Duration
 
 		// Status of the event stream.
 		readyState    ReadyState
@@ -33,37 +93,88 @@ type (
 
 // NewEventSource constructs returns an EventSource that satisfies the HTML5 EventSource specification.
 func NewEventSource(url string) (*EventSource, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewEventSourceWithRequest(http.DefaultClient, req)
+}
+
+// NewEventSourceWithContext constructs an EventSource tied to ctx: cancelling ctx aborts the
+// in-flight HTTP request, unblocks a pending Read on the response body, and causes consume() to
+// exit cleanly instead of reconnecting. Use Err() to distinguish user cancellation from a stream
+// error once MessageEvents() closes.
+func NewEventSourceWithContext(ctx context.Context, url string) (*EventSource, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewEventSourceWithRequest(http.DefaultClient, req.WithContext(ctx))
+}
+
+// NewEventSourceWithRequest constructs an EventSource using a caller-supplied client and request,
+// allowing custom timeouts, TLS configuration, proxies, cookie jars, authentication headers,
+// custom User-Agents, query params, or a non-default HTTP method. The request is cloned on every
+// connection attempt; the Accept, Cache-Control and Last-Event-ID headers required by the SSE
+// protocol are injected on top of whatever headers the caller has already set.
+func NewEventSourceWithRequest(client *http.Client, req *http.Request) (*EventSource, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	defaultCh := make(chan *MessageEvent, defaultListenerBuffer)
 	es := &EventSource{
-		d:   nil,
-		url: url,
-		out: make(chan *MessageEvent),
+		d:              nil,
+		url:            req.URL.String(),
+		client:         client,
+		req:            req,
+		backoff:        NewExponentialBackoff(0),
+		errs:           make(chan error, errorsChanBuffer),
+		done:           make(chan struct{}),
+		heartbeats:     make(chan struct{}, heartbeatsChanBuffer),
+		listeners:      map[string][]chan *MessageEvent{defaultEventName: {defaultCh}},
+		listenerBuffer: defaultListenerBuffer,
+		defaultCh:      defaultCh,
 	}
 	return es, es.connect()
 }
 
-// connect does a connection attempt, if the operation fails, attempt reconnecting
-// according to the spec.
+// connect does a connection attempt; if it fails transiently (e.g. a 5xx response or a
+// network error), retry through reconnect()'s backoff loop same as a drop mid-stream,
+// same as the spec requires for the initial connection as much as for any later one.
 func (es *EventSource) connect() (err error) {
 	es.setReadyState(Connecting)
 	err = es.connectOnce()
+	if err != nil && es.mustReconnect(err) {
+		err = es.reconnect(err)
+	}
 	if err != nil {
-		es.Close()
+		es.closeWithError(err)
 	}
 	return
 }
 
 // reconnect to the stream several until the operation succeeds or the conditions
-// to retry no longer hold true.
-func (es *EventSource) reconnect() (err error) {
+// to retry no longer hold true; err is the failure that triggered the reconnect.
+// Returns nil once connectOnce succeeds (at which point
+// a new consume() goroutine is already running the stream) or the terminal error once
+// retrying is no longer warranted. Callers must only treat a non-nil return as fatal:
+// closing the EventSource on a nil return would tear down the stream reconnect just
+// re-established.
+func (es *EventSource) reconnect(err error) error {
 	es.setReadyState(Connecting)
-	for es.mustReconnect(err) {
-		time.Sleep(time.Duration(es.d.Retry()) * time.Millisecond)
+	for es.mustReconnect(err) && es.req.Context().Err() == nil && !es.isClosing() {
+		es.attempt++
+		var serverRetry time.Duration
+		if es.d != nil {
+			serverRetry = time.Duration(es.d.Retry()) * time.Millisecond
+		}
+		time.Sleep(es.backoff.NextDelay(es.attempt, serverRetry))
 		err = es.connectOnce()
+		if err != nil {
+			es.sendError(err)
+		}
 	}
-	if err != nil {
-		es.Close()
-	}
-	return
+	return err
 }
 
 // Attempts to connect and updates internal status depending on the outcome.
@@ -72,18 +183,27 @@ func (es *EventSource) connectOnce() (err error) {
 	if err != nil {
 		return
 	}
+	if es.isClosing() {
+		// Close() raced with this connection attempt: tear it down without starting a
+		// consume() goroutine, which would otherwise dispatch to already-closed
+		// listener channels.
+		es.resp.Body.Close()
+		return nil
+	}
+	es.attempt = 0
 	es.setReadyState(Open)
+	if es.readTimeout > 0 {
+		es.resp.Body = newLivenessReader(es.resp.Body, es.readTimeout, es.heartbeats)
+	}
 	es.d = NewDecoder(es.resp.Body)
 	go es.consume()
 	return
 }
 
 func (es *EventSource) doHTTPConnect() (*http.Response, error) {
-	// Prepare request
-	req, err := http.NewRequest("GET", es.url, nil)
-	if err != nil {
-		return nil, err
-	}
+	// Clone the caller's request so headers set below don't leak between attempts,
+	// and so the caller's own headers, method and body are preserved on every reconnect.
+	req := es.req.Clone(es.req.Context())
 	req.Header.Set("Accept", AllowedContentType)
 	req.Header.Set("Cache-Control", "no-store")
 	if es.lastEventID != "" {
@@ -91,53 +211,162 @@ func (es *EventSource) doHTTPConnect() (*http.Response, error) {
 	}
 
 	// Check response
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := es.client.Do(req)
 	if err != nil {
 		return resp, err
 	}
+
+	// The client already followed any redirects (e.g. 301/307); adopt the final URL so
+	// that subsequent reconnect attempts target it directly instead of the original one.
+	if resp.Request != nil && resp.Request.URL.String() != es.URL() {
+		es.setURL(resp.Request.URL.String())
+		es.req.URL = resp.Request.URL
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// 200: open as usual.
+	case http.StatusNoContent:
+		// 204: permanent close, handled by mustReconnect.
+	case http.StatusResetContent:
+		// 205: reset Last-Event-ID and stop reconnecting.
+		es.lastEventID = ""
+		resp.Body.Close()
+		return resp, &SubscriptionError{Code: resp.StatusCode, Message: resp.Status, Permanent: true}
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return resp, &SubscriptionError{Code: resp.StatusCode, Message: readBodySnippet(resp), Permanent: true}
+	default:
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return resp, &SubscriptionError{Code: resp.StatusCode, Message: readBodySnippet(resp)}
+		}
+	}
+
 	if resp.Header.Get("Content-Type") != AllowedContentType {
+		resp.Body.Close()
 		return resp, ErrContentType
 	}
 	return resp, nil
 }
 
+// readBodySnippet closes resp.Body after reading and returning up to
+// subscriptionErrorBodySnippet bytes of it, trimmed, for use as a SubscriptionError's
+// Message. Falls back to the HTTP status line if the body is empty or unreadable.
+func readBodySnippet(resp *http.Response) string {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, subscriptionErrorBodySnippet))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return msg
+	}
+	return resp.Status
+}
+
 // Method consume() must be called once connect() succeeds.
 // It parses the input reader and assigns the event output channel accordingly.
 func (es *EventSource) consume() {
 	for {
 		ev, err := es.d.Decode()
 		if err != nil {
-			if es.mustReconnect(err) {
-				err = es.reconnect()
+			es.sendError(err)
+			if es.req.Context().Err() == nil && !es.isClosing() && es.mustReconnect(err) {
+				// A nil return means connectOnce succeeded and a new consume()
+				// goroutine is already running the re-established stream; closing
+				// here would tear that stream back down.
+				err = es.reconnect(err)
+			}
+			if err != nil {
+				es.closeWithError(err)
 			}
-			es.Close()
 			return
 		}
 		es.lastEventID = ev.LastEventID
-		es.out <- ev
+		es.dispatch(ev)
+	}
+}
+
+// dispatch fans ev out to every listener registered for its event name, plus the
+// default "message" channel (MessageEvents()) so existing consumers keep seeing every
+// event regardless of name, matching this package's historical behavior.
+func (es *EventSource) dispatch(ev *MessageEvent) {
+	name := ev.Name
+	if name == "" {
+		name = defaultEventName
+	}
+	es.listenersMux.Lock()
+	defer es.listenersMux.Unlock()
+	if es.closed {
+		return
+	}
+	es.sendToListenersLocked(name, ev)
+	if name != defaultEventName {
+		es.sendToListenersLocked(defaultEventName, ev)
+	}
+}
+
+// sendToListenersLocked delivers ev to every channel registered under name. Sends never
+// block: if a channel is full, the oldest queued event is dropped to make room.
+// Callers must hold listenersMux.
+func (es *EventSource) sendToListenersLocked(name string, ev *MessageEvent) {
+	for _, ch := range es.listeners[name] {
+		select {
+		case ch <- ev:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
 	}
 }
 
 // Clients will reconnect if the connection is closed;
 // a client can be told to stop reconnecting using the HTTP 204 No Content response code.
 func (es *EventSource) mustReconnect(err error) bool {
+	if err == nil {
+		return false
+	}
+	var subErr *SubscriptionError
+	if errors.As(err, &subErr) {
+		return !subErr.Permanent
+	}
 	switch err {
 	case ErrContentType:
 		return false
 	case io.ErrUnexpectedEOF:
 		return true
 	}
-	if es.resp != nil && es.resp.StatusCode == http.StatusNoContent {
-		return false
+	if es.resp != nil {
+		switch es.resp.StatusCode {
+		case http.StatusNoContent, http.StatusResetContent:
+			return false
+		}
 	}
 	return true
 }
 
 // Returns the event source URL.
 func (es *EventSource) URL() string {
+	es.urlMux.RLock()
+	defer es.urlMux.RUnlock()
 	return es.url
 }
 
+// setURL updates the event source URL, e.g. after following a redirect.
+func (es *EventSource) setURL(url string) {
+	es.urlMux.Lock()
+	defer es.urlMux.Unlock()
+	es.url = url
+}
+
+// Err returns the error from the EventSource's context, if any, allowing callers to
+// distinguish a user-initiated cancellation from a stream error once MessageEvents() closes.
+func (es *EventSource) Err() error {
+	return es.req.Context().Err()
+}
+
 // Returns the event source connection state, either connecting, open or closed.
 func (es *EventSource) ReadyState() ReadyState {
 	es.readyStateMux.RLock()
@@ -159,21 +388,141 @@ func (es *EventSource) setReadyState(newState ReadyState) {
 // Returns the channel of events. MessageEvents will be queued in the channel as they
 // are received.
 func (es *EventSource) MessageEvents() <-chan *MessageEvent {
-	return es.out
+	return es.defaultCh
+}
+
+// AddEventListener subscribes to events whose `event:` field equals name (the browser
+// EventSource.addEventListener model), returning a channel that receives only those
+// events. Listeners registered for "message" receive every event, same as
+// MessageEvents(). The channel is buffered per SetListenerBuffer (16 by default); once
+// full, the oldest queued event is dropped to make room for new ones.
+func (es *EventSource) AddEventListener(name string) <-chan *MessageEvent {
+	es.listenersMux.Lock()
+	defer es.listenersMux.Unlock()
+	ch := make(chan *MessageEvent, es.listenerBuffer)
+	es.listeners[name] = append(es.listeners[name], ch)
+	return ch
+}
+
+// RemoveEventListener unsubscribes and closes a channel previously returned by
+// AddEventListener for the given name. It is a no-op if ch is not currently registered.
+func (es *EventSource) RemoveEventListener(name string, ch <-chan *MessageEvent) {
+	es.listenersMux.Lock()
+	defer es.listenersMux.Unlock()
+	chans := es.listeners[name]
+	for i, c := range chans {
+		if c == ch {
+			es.listeners[name] = append(chans[:i], chans[i+1:]...)
+			close(c)
+			return
+		}
+	}
+}
+
+// SetListenerBuffer overrides the per-listener channel buffer used by subsequent calls
+// to AddEventListener. It does not affect already-registered listeners, including the
+// default "message" channel returned by MessageEvents().
+func (es *EventSource) SetListenerBuffer(n int) {
+	es.listenersMux.Lock()
+	defer es.listenersMux.Unlock()
+	es.listenerBuffer = n
+}
+
+// Errors returns a channel of decode and connection errors encountered while streaming,
+// including reconnect failures that would otherwise go unreported. Sends are non-blocking:
+// if the channel is full, the oldest queued error is dropped to make room.
+func (es *EventSource) Errors() <-chan error {
+	return es.errs
+}
+
+// sendError queues err on the Errors channel without blocking the caller, dropping the
+// oldest queued error first if the channel is full.
+func (es *EventSource) sendError(err error) {
+	for {
+		select {
+		case es.errs <- err:
+			return
+		default:
+		}
+		select {
+		case <-es.errs:
+		default:
+		}
+	}
+}
+
+// SetBackoff overrides the strategy used to compute delays between reconnect attempts.
+// The default is an ExponentialBackoff. Must be called before the EventSource starts
+// reconnecting to take effect.
+func (es *EventSource) SetBackoff(b Backoff) {
+	es.backoff = b
+}
+
+// SetReadTimeout enables an application-level liveness check: if no data is received on
+// the underlying connection within d, it is torn down and reconnected through the usual
+// backoff path. This guards against intermediaries (common on HTTP/2 deployments)
+// silently dropping an idle connection without closing it. A zero value, the default,
+// disables the check. Takes effect on the next connection attempt.
+func (es *EventSource) SetReadTimeout(d time.Duration) {
+	es.readTimeout = d
+}
+
+// Heartbeats returns a channel that receives a signal whenever an SSE comment line
+// (one starting with ":", conventionally used by servers as a keep-alive) is received.
+// Only populated while a ReadTimeout is set via SetReadTimeout, since that is what
+// drives the underlying liveness reader. The channel is buffered to 1; a heartbeat
+// arriving while one is already queued is dropped, since only recency matters.
+func (es *EventSource) Heartbeats() <-chan struct{} {
+	return es.heartbeats
 }
 
 // Closes the event source.
 // After closing the event source, it cannot be reused again.
 func (es *EventSource) Close() {
+	es.closeWithError(nil)
+}
+
+// Wait blocks until the EventSource has closed and returns the error that caused it to
+// stop reconnecting: a *SubscriptionError for a permanent HTTP failure (401, 403, 404,
+// 410 or 205), otherwise the connection or decode error that ended the stream, including
+// after a 204 response or context cancellation. It returns nil only when Close() was
+// called first.
+func (es *EventSource) Wait() error {
+	<-es.done
+	return es.finalErr
+}
+
+// closeWithError closes the EventSource, recording err as the result of a subsequent Wait().
+func (es *EventSource) closeWithError(err error) {
 	if es.acquireClosingRight() {
 		if es.resp != nil {
 			es.resp.Body.Close()
 		}
-		close(es.out)
+		es.finalErr = err
+		es.listenersMux.Lock()
+		es.closed = true
+		for _, chans := range es.listeners {
+			for _, ch := range chans {
+				close(ch)
+			}
+		}
+		es.listenersMux.Unlock()
+		close(es.done)
 		es.setReadyState(Closed)
 	}
 }
 
+// isClosing reports whether Close() has been called (or is in progress), so that
+// reconnect/consume loops racing with a concurrent Close() stop instead of dispatching
+// to listener channels Close() is about to (or has already) closed.
+func (es *EventSource) isClosing() bool {
+	switch es.ReadyState() {
+	case Closing, Closed:
+		return true
+	}
+	return false
+}
+
 // Acquires closing right by setting readyState to Closing if no one else
 // is attempting to close the EventSource.
 func (es *EventSource) acquireClosingRight() bool {
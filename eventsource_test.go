@@ -0,0 +1,247 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMustReconnectClassification(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		resp *http.Response
+		want bool
+	}{
+		{"nil error", nil, nil, false},
+		{"content type mismatch", ErrContentType, nil, false},
+		{"unexpected EOF", io.ErrUnexpectedEOF, nil, true},
+		{"permanent subscription error", &SubscriptionError{Code: 404, Permanent: true}, nil, false},
+		{"transient subscription error", &SubscriptionError{Code: 503}, nil, true},
+		{"204 no content", errors.New("boom"), &http.Response{StatusCode: http.StatusNoContent}, false},
+		{"205 reset content", errors.New("boom"), &http.Response{StatusCode: http.StatusResetContent}, false},
+		{"generic network error", errors.New("boom"), nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			es := &EventSource{resp: c.resp}
+			if got := es.mustReconnect(c.err); got != c.want {
+				t.Errorf("mustReconnect(%v) = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}
+
+func newTestEventSource() *EventSource {
+	return &EventSource{
+		listeners: map[string][]chan *MessageEvent{
+			defaultEventName: {make(chan *MessageEvent, 1)},
+		},
+		listenerBuffer: 1,
+	}
+}
+
+func TestDispatchFansOutToNamedAndDefaultListeners(t *testing.T) {
+	es := newTestEventSource()
+	fooCh := es.AddEventListener("foo")
+
+	ev := &MessageEvent{Name: "foo", Data: "payload"}
+	es.dispatch(ev)
+
+	select {
+	case got := <-fooCh:
+		if got != ev {
+			t.Fatalf("foo listener got %v, want %v", got, ev)
+		}
+	default:
+		t.Fatal("expected event on foo listener")
+	}
+
+	select {
+	case got := <-es.listeners[defaultEventName][0]:
+		if got != ev {
+			t.Fatalf("default listener got %v, want %v", got, ev)
+		}
+	default:
+		t.Fatal("named events must also reach the default \"message\" listener")
+	}
+}
+
+func TestDispatchDropsOldestWhenListenerFull(t *testing.T) {
+	es := newTestEventSource()
+	ch := es.AddEventListener("foo")
+
+	first := &MessageEvent{Name: "foo", Data: "first"}
+	second := &MessageEvent{Name: "foo", Data: "second"}
+	es.dispatch(first)
+	es.dispatch(second)
+
+	got := <-ch
+	if got != second {
+		t.Fatalf("expected the newest event to survive drop-oldest, got %v", got)
+	}
+	select {
+	case extra := <-ch:
+		t.Fatalf("expected only one queued event, got an extra one: %v", extra)
+	default:
+	}
+}
+
+func TestRemoveEventListenerClosesChannel(t *testing.T) {
+	es := newTestEventSource()
+	ch := es.AddEventListener("foo")
+	es.RemoveEventListener("foo", ch)
+
+	if _, open := <-ch; open {
+		t.Fatal("expected channel to be closed after RemoveEventListener")
+	}
+}
+
+func sseTestServer(t *testing.T, events []*MessageEvent, keepOpen <-chan struct{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", AllowedContentType)
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+		for _, ev := range events {
+			io.WriteString(w, messageEventToString(ev))
+			flusher.Flush()
+		}
+		if keepOpen != nil {
+			<-keepOpen
+		}
+	}))
+}
+
+func TestAddEventListenerReceivesOnlyMatchingEvents(t *testing.T) {
+	keepOpen := make(chan struct{})
+	srv := sseTestServer(t, []*MessageEvent{
+		newMessageEvent("", "greeting", 0),
+		newMessageEvent("", "", 0),
+	}, keepOpen)
+	defer srv.Close()
+	defer close(keepOpen)
+
+	es, err := NewEventSource(srv.URL)
+	if err != nil {
+		t.Fatalf("NewEventSource: %v", err)
+	}
+	defer es.Close()
+
+	greetings := es.AddEventListener("greeting")
+
+	select {
+	case ev := <-greetings:
+		if ev.Name != "greeting" {
+			t.Fatalf("greeting listener got event named %q", ev.Name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the \"greeting\" event")
+	}
+
+	// MessageEvents() receives every event, named ones included, in arrival order.
+	for _, want := range []string{"greeting", ""} {
+		select {
+		case ev := <-es.MessageEvents():
+			if ev.Name != want {
+				t.Fatalf("MessageEvents() got event named %q, want %q", ev.Name, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the default event")
+		}
+	}
+}
+
+func TestWaitReturnsSubscriptionErrorOnPermanentFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "not found")
+	}))
+	defer srv.Close()
+
+	es, _ := NewEventSource(srv.URL)
+	err := es.Wait()
+
+	var subErr *SubscriptionError
+	if !errors.As(err, &subErr) {
+		t.Fatalf("Wait() = %v, want a *SubscriptionError", err)
+	}
+	if subErr.Code != http.StatusNotFound || !subErr.Permanent {
+		t.Fatalf("got %+v, want Code=404 Permanent=true", subErr)
+	}
+}
+
+// TestConsumeSurvivesReconnectAndKeepsDelivering drives a real drop->reconnect->deliver
+// cycle: the first connection is cut after one event, forcing consume() to reconnect,
+// and asserts events keep flowing afterward instead of the EventSource closing itself
+// (the bug fixed alongside this request: the pre-reconnect consume() goroutine used to
+// unconditionally tear down the stream reconnect() had just re-established).
+func TestConsumeSurvivesReconnectAndKeepsDelivering(t *testing.T) {
+	var attempts int32
+	keepOpen := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", AllowedContentType)
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			io.WriteString(w, messageEventToString(newMessageEvent("", "", 0)))
+			flusher.Flush()
+			return // drop the connection to force a reconnect
+		}
+		io.WriteString(w, messageEventToString(newMessageEvent("", "", 0)))
+		flusher.Flush()
+		<-keepOpen
+	}))
+	defer srv.Close()
+	defer close(keepOpen)
+
+	es, err := NewEventSource(srv.URL)
+	if err != nil {
+		t.Fatalf("NewEventSource: %v", err)
+	}
+	defer es.Close()
+
+	select {
+	case <-es.MessageEvents():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the pre-reconnect event")
+	}
+
+	select {
+	case <-es.MessageEvents():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the post-reconnect event")
+	}
+
+	if atomic.LoadInt32(&attempts) < 2 {
+		t.Fatalf("expected at least 2 connection attempts, got %d", attempts)
+	}
+	if es.ReadyState() == Closed {
+		t.Fatal("EventSource closed itself after a successful reconnect")
+	}
+}
+
+func TestErrReflectsContextCancellation(t *testing.T) {
+	keepOpen := make(chan struct{})
+	srv := sseTestServer(t, nil, keepOpen)
+	defer srv.Close()
+	defer close(keepOpen)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	es, err := NewEventSourceWithContext(ctx, srv.URL)
+	if err != nil {
+		t.Fatalf("NewEventSourceWithContext: %v", err)
+	}
+
+	cancel()
+	es.Wait()
+
+	if !errors.Is(es.Err(), context.Canceled) {
+		t.Fatalf("Err() = %v, want context.Canceled", es.Err())
+	}
+}
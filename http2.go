@@ -0,0 +1,18 @@
+package sse
+
+import (
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// NewHTTP2Client returns an *http.Client whose Transport is forced to HTTP/2 via
+// transport, for use with NewEventSourceWithRequest. Real-world SSE deployments
+// increasingly run over HTTP/2, where idle connections can otherwise be silently
+// dropped by intermediaries. Pass nil to get a transport with the library defaults.
+func NewHTTP2Client(transport *http2.Transport) *http.Client {
+	if transport == nil {
+		transport = &http2.Transport{}
+	}
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,60 @@
+package sse
+
+import (
+	"bufio"
+	"io"
+	"time"
+)
+
+// livenessReader wraps an SSE response body and enforces a read timeout: any line on
+// the wire resets the timer, including SSE comment/heartbeat lines starting with ":".
+// Those comment lines are passed through to the decoder unchanged (so decoding is
+// unaffected) but are also reported on heartbeats before being returned, so callers
+// can observe server keep-alives via EventSource.Heartbeats(). If nothing arrives
+// within timeout, the underlying body is closed, unblocking the decoder's Read with an
+// error so the existing mustReconnect/reconnect path tears the stale connection down
+// and reconnects.
+type livenessReader struct {
+	body       io.ReadCloser
+	src        *bufio.Reader
+	timer      *time.Timer
+	timeout    time.Duration
+	heartbeats chan struct{}
+
+	buf []byte
+	err error
+}
+
+func newLivenessReader(body io.ReadCloser, timeout time.Duration, heartbeats chan struct{}) *livenessReader {
+	r := &livenessReader{body: body, src: bufio.NewReader(body), timeout: timeout, heartbeats: heartbeats}
+	r.timer = time.AfterFunc(timeout, func() { body.Close() })
+	return r
+}
+
+func (r *livenessReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 && r.err == nil {
+		line, err := r.src.ReadBytes('\n')
+		if len(line) > 0 {
+			r.timer.Reset(r.timeout)
+			if line[0] == ':' {
+				select {
+				case r.heartbeats <- struct{}{}:
+				default:
+				}
+			}
+			r.buf = line
+		}
+		r.err = err
+	}
+	if len(r.buf) > 0 {
+		n := copy(p, r.buf)
+		r.buf = r.buf[n:]
+		return n, nil
+	}
+	return 0, r.err
+}
+
+func (r *livenessReader) Close() error {
+	r.timer.Stop()
+	return r.body.Close()
+}